@@ -0,0 +1,193 @@
+package midterm
+
+import "github.com/danielgatis/go-ansicode"
+
+// HyperlinkID references an interned hyperlink (id, uri) pair. The zero
+// value means "no hyperlink".
+type HyperlinkID uint32
+
+// hyperlinkEntry is a single interned OSC 8 hyperlink, refcounted by the
+// number of cells currently stamped with it.
+type hyperlinkEntry struct {
+	ID       string
+	URI      string
+	refcount int
+}
+
+type hyperlinkKey struct {
+	id, uri string
+}
+
+// hyperlinkTable interns hyperlinks so that identical (id, uri) pairs -
+// which is the common case, since a run of cells under one OSC 8 span all
+// share it - store a single entry.
+type hyperlinkTable struct {
+	byID  map[HyperlinkID]*hyperlinkEntry
+	byKey map[hyperlinkKey]HyperlinkID
+	next  HyperlinkID
+}
+
+func newHyperlinkTable() *hyperlinkTable {
+	return &hyperlinkTable{
+		byID:  map[HyperlinkID]*hyperlinkEntry{},
+		byKey: map[hyperlinkKey]HyperlinkID{},
+	}
+}
+
+// intern returns the id for (id, uri), creating an entry if this is the
+// first time this pair has been seen. Either way, the returned id is ref'd
+// once on the caller's behalf - SetHyperlink uses this to give the cursor
+// its own claim on the entry, so a hyperlink opened and closed without ever
+// being stamped onto a cell is still unreffed back to zero instead of
+// leaking.
+func (t *hyperlinkTable) intern(id, uri string) HyperlinkID {
+	key := hyperlinkKey{id, uri}
+	if hid, ok := t.byKey[key]; ok {
+		t.ref(hid)
+		return hid
+	}
+	t.next++
+	hid := t.next
+	t.byID[hid] = &hyperlinkEntry{ID: id, URI: uri}
+	t.byKey[key] = hid
+	t.ref(hid)
+	return hid
+}
+
+func (t *hyperlinkTable) ref(id HyperlinkID) {
+	if id == 0 {
+		return
+	}
+	if e, ok := t.byID[id]; ok {
+		e.refcount++
+	}
+}
+
+func (v *Screen) unrefHyperlink(id HyperlinkID) {
+	if id == 0 {
+		return
+	}
+	t := v.linkTable
+	e, ok := t.byID[id]
+	if !ok {
+		return
+	}
+	e.refcount--
+	if e.refcount <= 0 {
+		delete(t.byID, id)
+		delete(t.byKey, hyperlinkKey{e.ID, e.URI})
+	}
+}
+
+// stampHyperlink sets the hyperlink at (y, x) to id, adjusting refcounts on
+// both the outgoing and incoming entries.
+func (v *Screen) stampHyperlink(y, x int, id HyperlinkID) {
+	v.unrefHyperlink(v.Hyperlinks[y][x])
+	v.Hyperlinks[y][x] = id
+	v.linkTable.ref(id)
+}
+
+// unrefHyperlinkRange unrefs every hyperlink in row y's columns [x, x+n),
+// clamped to the row's bounds. Used before a cell's old hyperlink is
+// discarded without being copied anywhere else, e.g. characters falling off
+// the end of a row on insert/delete.
+func (v *Screen) unrefHyperlinkRange(y, x, n int) {
+	if y < 0 || y >= len(v.Hyperlinks) || n <= 0 {
+		return
+	}
+	row := v.Hyperlinks[y]
+	start, end := x, x+n
+	if start < 0 {
+		start = 0
+	}
+	if end > len(row) {
+		end = len(row)
+	}
+	for i := start; i < end; i++ {
+		v.unrefHyperlink(row[i])
+	}
+}
+
+// unrefHyperlinkRows unrefs every hyperlink on rows [fromRow, toRow],
+// clamped to the screen's bounds. Used before whole rows are discarded
+// without being copied anywhere else, e.g. lines scrolled off a
+// sub-region or deleted outright.
+func (v *Screen) unrefHyperlinkRows(fromRow, toRow int) {
+	if fromRow < 0 {
+		fromRow = 0
+	}
+	for y := fromRow; y <= toRow && y < len(v.Hyperlinks); y++ {
+		v.unrefHyperlinkRange(y, 0, len(v.Hyperlinks[y]))
+	}
+}
+
+// SetHyperlink implements the ansicode decoder's Handler interface for OSC
+// 8: hyperlink is non-nil for the opening form, and nil for the terminator
+// that ends it. While a hyperlink is active, every subsequent put stamps
+// the cell it writes with it.
+//
+// The cursor holds its own ref on whatever hyperlink is currently active,
+// separate from the refs stampHyperlink takes per cell, so a hyperlink
+// opened and closed again without a single character printed in between -
+// a legal zero-width OSC 8 - is still unreffed back to zero instead of
+// sitting in the table forever.
+func (v *Terminal) SetHyperlink(hyperlink *ansicode.Hyperlink) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.unrefHyperlink(v.Cursor.Hyperlink)
+	if hyperlink == nil {
+		v.Cursor.Hyperlink = 0
+		return
+	}
+	v.Cursor.Hyperlink = v.linkTable.intern(hyperlink.ID, hyperlink.URI)
+}
+
+// HyperlinkAt returns the hyperlink covering cell (y, x), if any.
+func (s *Screen) HyperlinkAt(y, x int) (uri, id string, ok bool) {
+	if y < 0 || y >= len(s.Hyperlinks) || x < 0 || x >= len(s.Hyperlinks[y]) {
+		return "", "", false
+	}
+	hid := s.Hyperlinks[y][x]
+	if hid == 0 {
+		return "", "", false
+	}
+	e, ok := s.linkTable.byID[hid]
+	if !ok {
+		return "", "", false
+	}
+	return e.URI, e.ID, true
+}
+
+// HyperlinkRun is a contiguous, inclusive span of columns sharing one
+// hyperlink.
+type HyperlinkRun struct {
+	Start, End int
+	URI, ID    string
+}
+
+// HyperlinkRuns groups row y's cells into contiguous runs of the same
+// hyperlink, so a renderer can underline a whole link in one pass instead
+// of checking every cell.
+func (s *Screen) HyperlinkRuns(y int) []HyperlinkRun {
+	if y < 0 || y >= len(s.Hyperlinks) {
+		return nil
+	}
+
+	row := s.Hyperlinks[y]
+	var runs []HyperlinkRun
+	for x := 0; x < len(row); {
+		hid := row[x]
+		if hid == 0 {
+			x++
+			continue
+		}
+		start := x
+		for x < len(row) && row[x] == hid {
+			x++
+		}
+		if e, ok := s.linkTable.byID[hid]; ok {
+			runs = append(runs, HyperlinkRun{Start: start, End: x - 1, URI: e.URI, ID: e.ID})
+		}
+	}
+	return runs
+}
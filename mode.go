@@ -0,0 +1,178 @@
+package midterm
+
+import "fmt"
+
+// TermMode is a bitset of terminal modes, mirroring Alacritty's TermMode:
+// a single consolidated place for the DECSET/DECRST flags that used to be
+// scattered across individual bool fields.
+type TermMode uint32
+
+const (
+	ShowCursor TermMode = 1 << iota
+	AppCursor
+	AppKeypad
+	BracketedPaste
+	MouseReportClick
+	MouseReportMotion
+	MouseReportAny
+	SgrMouse
+	FocusInOut
+	AltScreen
+	LineWrap
+	Origin
+	Insert
+	AutoRepeat
+	AltSendsEsc
+)
+
+// defaultTermMode is what a freshly reset terminal starts with.
+const defaultTermMode = ShowCursor | LineWrap | AutoRepeat
+
+// Mode returns the terminal's current mode bitset.
+func (v *Terminal) Mode() TermMode {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	return v.mode
+}
+
+// SetMode turns on every bit set in m.
+func (v *Terminal) SetMode(m TermMode) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	turnedOn := m &^ v.mode
+	v.mode |= m
+	if turnedOn&AltScreen != 0 {
+		v.swapAlt()
+	}
+}
+
+// UnsetMode turns off every bit set in m.
+func (v *Terminal) UnsetMode(m TermMode) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	turnedOff := m & v.mode
+	v.mode &^= m
+	if turnedOff&AltScreen != 0 {
+		v.swapAlt()
+	}
+}
+
+// privateModeBits maps a DECSET/DECRST private mode number (the Ps in
+// "CSI ? Ps h" / "CSI ? Ps l") to the TermMode bit it controls.
+var privateModeBits = map[int]TermMode{
+	1:    AppCursor,
+	7:    LineWrap,
+	25:   ShowCursor,
+	1000: MouseReportClick,
+	1002: MouseReportMotion,
+	1003: MouseReportAny,
+	1004: FocusInOut,
+	1006: SgrMouse,
+	1049: AltScreen,
+	2004: BracketedPaste,
+}
+
+// SetPrivateMode handles CSI ? Ps h (DECSET, set true) and CSI ? Ps l
+// (DECRST, set false), translating the mode number onto the TermMode
+// bitset. Unrecognized Ps values are ignored.
+func (v *Terminal) SetPrivateMode(param int, set bool) {
+	bit, ok := privateModeBits[param]
+	if !ok {
+		return
+	}
+	if set {
+		v.SetMode(bit)
+	} else {
+		v.UnsetMode(bit)
+	}
+}
+
+const (
+	bracketedPasteStart = "\x1b[200~"
+	bracketedPasteEnd   = "\x1b[201~"
+)
+
+// WrapPaste wraps s in bracketed-paste markers if BracketedPaste mode is
+// set, so the program on the other end can tell pasted text apart from
+// typed input; otherwise it returns s unchanged.
+func (v *Terminal) WrapPaste(s string) string {
+	if v.Mode()&BracketedPaste == 0 {
+		return s
+	}
+	return bracketedPasteStart + s + bracketedPasteEnd
+}
+
+// MouseEventKind distinguishes a button press/release from pointer motion.
+type MouseEventKind int
+
+const (
+	MousePress MouseEventKind = iota
+	MouseRelease
+	MouseMotion
+)
+
+// MouseButton identifies which button (or wheel direction) a mouse event
+// reports.
+type MouseButton int
+
+const (
+	MouseButtonLeft MouseButton = iota
+	MouseButtonMiddle
+	MouseButtonRight
+	MouseWheelUp
+	MouseWheelDown
+)
+
+// EncodeMouseEvent encodes a mouse event at the given 1-based row/col in
+// whichever mouse protocol is currently enabled (SGR if SgrMouse is set,
+// otherwise the legacy normal-mouse protocol), or "" if no mouse reporting
+// mode currently applies to this kind of event.
+func (v *Terminal) EncodeMouseEvent(kind MouseEventKind, button MouseButton, row, col int) string {
+	mode := v.Mode()
+
+	switch kind {
+	case MouseMotion:
+		if mode&(MouseReportMotion|MouseReportAny) == 0 {
+			return ""
+		}
+	default:
+		if mode&(MouseReportClick|MouseReportMotion|MouseReportAny) == 0 {
+			return ""
+		}
+	}
+
+	isWheel := button == MouseWheelUp || button == MouseWheelDown
+	cb := int(button)
+	if isWheel {
+		// xterm reserves 64/65 for the wheel buttons rather than packing
+		// them in with the ordinary button numbers.
+		cb = 64 + int(button-MouseWheelUp)
+	}
+	if kind == MouseMotion {
+		cb |= 32
+	}
+
+	if mode&SgrMouse != 0 {
+		final := byte('M')
+		if kind == MouseRelease {
+			final = 'm'
+		}
+		return fmt.Sprintf("\x1b[<%d;%d;%d%c", cb, col, row, final)
+	}
+
+	// Legacy protocol has no release-button identity and packs coordinates
+	// into single bytes offset by 32, so it can't address terminals larger
+	// than 223 columns/rows. The wheel has no release event, so it never
+	// hits the release sentinel below.
+	if kind == MouseRelease && !isWheel {
+		cb = 3
+	}
+	return fmt.Sprintf("\x1b[M%c%c%c", byte(cb+32), byte(clampCoord(col)+32), byte(clampCoord(row)+32))
+}
+
+func clampCoord(n int) int {
+	if n > 223 {
+		return 223
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package midterm
+
+import "testing"
+
+func TestEncodeMouseEventSgrWheel(t *testing.T) {
+	v := NewTerminal(24, 80)
+	v.SetMode(SgrMouse | MouseReportClick)
+
+	got := v.EncodeMouseEvent(MousePress, MouseWheelUp, 5, 10)
+	want := "\x1b[<64;10;5M"
+	if got != want {
+		t.Fatalf("wheel up: got %q, want %q", got, want)
+	}
+
+	got = v.EncodeMouseEvent(MousePress, MouseWheelDown, 5, 10)
+	want = "\x1b[<65;10;5M"
+	if got != want {
+		t.Fatalf("wheel down: got %q, want %q", got, want)
+	}
+}
+
+func TestEncodeMouseEventLegacyWheelDoesNotCollideWithRelease(t *testing.T) {
+	v := NewTerminal(24, 80)
+	v.SetMode(MouseReportClick)
+
+	wheel := v.EncodeMouseEvent(MousePress, MouseWheelUp, 5, 10)
+	release := v.EncodeMouseEvent(MouseRelease, MouseButtonLeft, 5, 10)
+	if wheel == release {
+		t.Fatalf("wheel-up press encoded the same as a button release: %q", wheel)
+	}
+	if wheel[3] != byte(64+32) {
+		t.Fatalf("legacy wheel-up cb byte = %d, want %d", wheel[3], 64+32)
+	}
+}
+
+func TestEncodeMouseEventClickRoundTrip(t *testing.T) {
+	v := NewTerminal(24, 80)
+	v.SetMode(MouseReportClick)
+
+	press := v.EncodeMouseEvent(MousePress, MouseButtonLeft, 5, 10)
+	wantPress := "\x1b[M" + string(rune(0+32)) + string(rune(10+32)) + string(rune(5+32))
+	if press != wantPress {
+		t.Fatalf("legacy press: got %q, want %q", press, wantPress)
+	}
+
+	release := v.EncodeMouseEvent(MouseRelease, MouseButtonLeft, 5, 10)
+	wantRelease := "\x1b[M" + string(rune(3+32)) + string(rune(10+32)) + string(rune(5+32))
+	if release != wantRelease {
+		t.Fatalf("legacy release: got %q, want %q", release, wantRelease)
+	}
+
+	v.SetMode(SgrMouse)
+	press = v.EncodeMouseEvent(MousePress, MouseButtonLeft, 5, 10)
+	if press != "\x1b[<0;10;5M" {
+		t.Fatalf("sgr press: got %q", press)
+	}
+	release = v.EncodeMouseEvent(MouseRelease, MouseButtonLeft, 5, 10)
+	if release != "\x1b[<0;10;5m" {
+		t.Fatalf("sgr release: got %q", release)
+	}
+}
+
+func TestEncodeMouseEventNoReportingMode(t *testing.T) {
+	v := NewTerminal(24, 80)
+	if got := v.EncodeMouseEvent(MousePress, MouseButtonLeft, 1, 1); got != "" {
+		t.Fatalf("expected no output with no reporting mode set, got %q", got)
+	}
+}
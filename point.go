@@ -0,0 +1,10 @@
+package midterm
+
+// Point identifies a single cell using the same coordinate space as Cursor:
+// Y increases downward from the top of the visible screen, and Y values
+// below zero reach back into scrollback history (Y == -1 is the line
+// immediately above the visible screen), matching Alacritty's Grid history
+// indexing.
+type Point struct {
+	Y, X int
+}
@@ -10,14 +10,37 @@ type Screen struct {
 	// Format is the display properties of each cell.
 	Format [][]Format
 
+	// Wrapped indicates, for each row, whether the line continues onto the
+	// next row as a soft wrap rather than ending with a hard line break.
+	// It is kept in lock-step with Content and Format.
+	Wrapped []bool
+
+	// Wide marks cells that are part of a fullwidth rune: the cell holding
+	// the glyph is WideLead, and the cell immediately after it is
+	// WideSpacer. All other cells are WideNone.
+	Wide [][]WideKind
+
+	// Combining holds zero-width combining runes (e.g. combining accents)
+	// attached to the preceding non-spacer cell. Most cells have none.
+	Combining [][][]rune
+
+	// Hyperlinks references, per cell, the interned hyperlink (if any) it
+	// was printed under. Zero means no hyperlink.
+	Hyperlinks [][]HyperlinkID
+
+	// linkTable interns hyperlink (id, uri) pairs so identical links share
+	// storage; shared with the alternate screen so it survives swaps.
+	linkTable *hyperlinkTable
+
 	// Cursor is the current state of the cursor.
 	Cursor Cursor
 }
 
 func newScreen(h, w int) *Screen {
 	s := &Screen{
-		Height: h,
-		Width:  w,
+		Height:    h,
+		Width:     w,
+		linkTable: newHyperlinkTable(),
 	}
 	s.reset()
 	return s
@@ -26,15 +49,23 @@ func newScreen(h, w int) *Screen {
 func (s *Screen) reset() {
 	s.Content = make([][]rune, s.Height)
 	s.Format = make([][]Format, s.Height)
+	s.Wrapped = make([]bool, s.Height)
+	s.Wide = make([][]WideKind, s.Height)
+	s.Combining = make([][][]rune, s.Height)
+	s.Hyperlinks = make([][]HyperlinkID, s.Height)
 	for row := 0; row < s.Height; row++ {
 		s.Content[row] = make([]rune, s.Width)
 		s.Format[row] = make([]Format, s.Width)
+		s.Wide[row] = make([]WideKind, s.Width)
+		s.Combining[row] = make([][]rune, s.Width)
+		s.Hyperlinks[row] = make([]HyperlinkID, s.Width)
 		for col := 0; col < s.Width; col++ {
 			s.Content[row][col] = ' '
 		}
 	}
 	s.Cursor.X = 0
 	s.Cursor.Y = 0
+	s.linkTable = newHyperlinkTable()
 }
 
 func (v *Screen) resize(h, w int) {
@@ -43,6 +74,10 @@ func (v *Screen) resize(h, w int) {
 		for row := 0; row < n; row++ {
 			v.Content = append(v.Content, make([]rune, v.Width))
 			v.Format = append(v.Format, make([]Format, v.Width))
+			v.Wrapped = append(v.Wrapped, false)
+			v.Wide = append(v.Wide, make([]WideKind, v.Width))
+			v.Combining = append(v.Combining, make([][]rune, v.Width))
+			v.Hyperlinks = append(v.Hyperlinks, make([]HyperlinkID, v.Width))
 			for col := 0; col < v.Width; col++ {
 				v.clear(v.Height+row, col, Format{})
 			}
@@ -50,6 +85,10 @@ func (v *Screen) resize(h, w int) {
 	} else if h < v.Height {
 		v.Content = v.Content[:h]
 		v.Format = v.Format[:h]
+		v.Wrapped = v.Wrapped[:h]
+		v.Wide = v.Wide[:h]
+		v.Combining = v.Combining[:h]
+		v.Hyperlinks = v.Hyperlinks[:h]
 	}
 
 	if w > v.Width {
@@ -60,6 +99,15 @@ func (v *Screen) resize(h, w int) {
 			format := make([]Format, w)
 			copy(format, v.Format[i])
 			v.Format[i] = format
+			wide := make([]WideKind, w)
+			copy(wide, v.Wide[i])
+			v.Wide[i] = wide
+			combining := make([][]rune, w)
+			copy(combining, v.Combining[i])
+			v.Combining[i] = combining
+			hyperlinks := make([]HyperlinkID, w)
+			copy(hyperlinks, v.Hyperlinks[i])
+			v.Hyperlinks[i] = hyperlinks
 			for j := v.Width; j < w; j++ {
 				v.clear(i, j, Format{})
 			}
@@ -68,6 +116,9 @@ func (v *Screen) resize(h, w int) {
 		for i := range v.Content {
 			v.Content[i] = v.Content[i][:w]
 			v.Format[i] = v.Format[i][:w]
+			v.Wide[i] = v.Wide[i][:w]
+			v.Combining[i] = v.Combining[i][:w]
+			v.Hyperlinks[i] = v.Hyperlinks[i][:w]
 		}
 	}
 }
@@ -78,4 +129,32 @@ func (v *Screen) clear(y, x int, format Format) {
 	}
 	v.Content[y][x] = ' '
 	v.Format[y][x] = format
-}
\ No newline at end of file
+	v.Wide[y][x] = WideNone
+	v.Combining[y][x] = nil
+	v.unrefHyperlink(v.Hyperlinks[y][x])
+	v.Hyperlinks[y][x] = 0
+}
+
+// CellAt returns a self-contained view of the cell at (y, x), joining the
+// base rune with its width and any zero-width combining marks so a renderer
+// doesn't need to know about Wide/Combining itself.
+func (s *Screen) CellAt(y, x int) Cell {
+	if y < 0 || y >= len(s.Content) || x < 0 || x >= len(s.Content[y]) {
+		return Cell{}
+	}
+
+	width := 1
+	switch s.Wide[y][x] {
+	case WideLead:
+		width = 2
+	case WideSpacer:
+		width = 0
+	}
+
+	return Cell{
+		Rune:      s.Content[y][x],
+		Combining: s.Combining[y][x],
+		Width:     width,
+		Format:    s.Format[y][x],
+	}
+}
@@ -0,0 +1,377 @@
+package midterm
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultScrollbackLimit is the number of scrollback lines retained when
+// SetScrollbackLimit has never been called.
+const DefaultScrollbackLimit = 10000
+
+// maxWrappedLinesFollowed bounds how many soft-wrapped rows Search will join
+// into a single logical line, so a pathological wall of wrapped text can't
+// make a search unbounded.
+const maxWrappedLinesFollowed = 100
+
+// Line is a single row of terminal content, either still visible on screen
+// or retained in scrollback.
+type Line struct {
+	// Content is the text of the line.
+	Content []rune
+
+	// Format is the display properties of each rune in Content.
+	Format []Format
+
+	// Wrapped indicates that this line continues onto the next row as a
+	// soft wrap, rather than ending with a hard line break.
+	Wrapped bool
+
+	// Wide classifies each cell's participation in a fullwidth rune; see
+	// WideKind.
+	Wide []WideKind
+
+	// Combining holds zero-width combining runes attached to each cell.
+	Combining [][]rune
+
+	// Hyperlinks references, per cell, the interned hyperlink (if any) it
+	// was printed under. Zero means no hyperlink. Scrolling a line into
+	// scrollback transfers its hyperlink refs here rather than dropping
+	// them.
+	Hyperlinks []HyperlinkID
+}
+
+// SetScrollbackLimit sets the maximum number of lines retained in
+// scrollback, trimming immediately if the current history exceeds it. A
+// limit of zero or less restores DefaultScrollbackLimit.
+func (v *Terminal) SetScrollbackLimit(n int) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.scrollbackLimit = n
+	limit := n
+	if limit <= 0 {
+		limit = DefaultScrollbackLimit
+	}
+	if len(v.scrollback) > limit {
+		v.evictScrollback(v.scrollback[:len(v.scrollback)-limit])
+		v.scrollback = v.scrollback[len(v.scrollback)-limit:]
+	}
+}
+
+// Scrollback returns a copy of the lines that have scrolled off the top of
+// the visible screen, oldest first.
+func (v *Terminal) Scrollback() []Line {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	out := make([]Line, len(v.scrollback))
+	copy(out, v.scrollback)
+	return out
+}
+
+// pushScrollback appends line to the scrollback buffer, notifies
+// onScrollback, and trims to the configured limit.
+func (v *Terminal) pushScrollback(line Line) {
+	if v.onScrollback != nil {
+		v.onScrollback(line)
+	}
+
+	limit := v.scrollbackLimit
+	if limit <= 0 {
+		limit = DefaultScrollbackLimit
+	}
+
+	v.scrollback = append(v.scrollback, line)
+	if len(v.scrollback) > limit {
+		v.evictScrollback(v.scrollback[:len(v.scrollback)-limit])
+		v.scrollback = v.scrollback[len(v.scrollback)-limit:]
+	}
+}
+
+// evictScrollback unrefs the hyperlinks held by lines about to be dropped
+// from scrollback entirely, so trimming the history doesn't leak
+// hyperlinkTable entries.
+func (v *Terminal) evictScrollback(lines []Line) {
+	for _, l := range lines {
+		for _, id := range l.Hyperlinks {
+			v.unrefHyperlink(id)
+		}
+	}
+}
+
+// Line returns the line at row, where row 0 is the top of the visible
+// screen and negative rows reach back into scrollback (-1 being the line
+// immediately above the visible screen), without copying the rest of the
+// history the way Scrollback does. The second return value is false if
+// row is out of range in either direction.
+func (v *Terminal) Line(row int) (Line, bool) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	return v.line(row)
+}
+
+// line is Line without locking, for internal callers that already hold
+// v.mut.
+func (v *Terminal) line(row int) (Line, bool) {
+	if row >= 0 {
+		if row >= len(v.Content) {
+			return Line{}, false
+		}
+		return Line{
+			Content:    v.Content[row],
+			Format:     v.Format[row],
+			Wrapped:    v.Wrapped[row],
+			Wide:       v.Wide[row],
+			Combining:  v.Combining[row],
+			Hyperlinks: v.Hyperlinks[row],
+		}, true
+	}
+
+	idx := len(v.scrollback) + row
+	if idx < 0 || idx >= len(v.scrollback) {
+		return Line{}, false
+	}
+	return v.scrollback[idx], true
+}
+
+// Direction is the direction a search walks relative to its starting point.
+type Direction int
+
+const (
+	// Forward searches toward the bottom of the screen.
+	Forward Direction = iota
+
+	// Backward searches toward the top of scrollback.
+	Backward
+)
+
+// Match is a single regex match, with Start and End identifying the first
+// and last matched cell.
+type Match struct {
+	Start, End Point
+}
+
+// MatchIterator lazily walks matches of a compiled pattern across the
+// visible screen and scrollback, so a large history doesn't have to be
+// searched eagerly up front.
+type MatchIterator struct {
+	v    *Terminal
+	re   *regexp.Regexp
+	dir  Direction
+	row  int
+	done bool
+
+	// startRow/startCol are the original Point Search was called with.
+	// constrain is true until the logical line containing startRow has
+	// been searched once, so start's column only bounds that first
+	// search and not any row visited afterward.
+	startRow  int
+	startCol  int
+	constrain bool
+
+	// resume is true after a match, so the next call searches the same
+	// logical line again, bounded by resumeAt (a byte offset into it),
+	// before moving on to the next logical line.
+	resume   bool
+	resumeAt int
+}
+
+// Search compiles pattern as a regular expression and returns an iterator
+// over its matches, starting at start and walking in direction. Matches on
+// start's own row are only reported at or after start.X (Forward) or at or
+// before start.X (Backward); rows joined by a soft wrap (see Line.Wrapped)
+// are searched as a single logical line, up to maxWrappedLinesFollowed
+// rows per match.
+func (v *Terminal) Search(pattern string, direction Direction, start Point) (*MatchIterator, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &MatchIterator{
+		v: v, re: re, dir: direction, row: start.Y,
+		startRow: start.Y, startCol: start.X, constrain: true,
+	}, nil
+}
+
+// Next returns the next match in the iterator's direction, or false once
+// there is nothing left to search.
+func (it *MatchIterator) Next() (Match, bool) {
+	if it.done {
+		return Match{}, false
+	}
+
+	it.v.mut.Lock()
+	defer it.v.mut.Unlock()
+
+	minRow := -len(it.v.scrollback)
+	maxRow := len(it.v.Content) - 1
+
+	for it.row >= minRow && it.row <= maxRow {
+		rows, texts := it.v.logicalLine(it.row)
+		if len(rows) == 0 {
+			break
+		}
+
+		// Where to go once this logical line has no more matches.
+		var advance int
+		if it.dir == Forward {
+			advance = rows[len(rows)-1] + 1
+		} else {
+			advance = rows[0] - 1
+		}
+
+		joined := strings.Join(texts, "")
+		minStart, maxStart := 0, len(joined)
+		if it.constrain {
+			if off, ok := colByteOffset(rows, texts, it.startRow, it.startCol); ok {
+				if it.dir == Forward {
+					minStart = off
+				} else {
+					maxStart = off
+				}
+			}
+			it.constrain = false
+		}
+		if it.resume {
+			if it.dir == Forward {
+				minStart = it.resumeAt
+			} else {
+				maxStart = it.resumeAt
+			}
+			it.resume = false
+		}
+
+		if loc := it.findInLine(joined, minStart, maxStart); loc != nil {
+			start, end := pointsForMatch(rows, texts, loc[0], loc[1])
+			it.resume = true
+			if it.dir == Forward {
+				it.resumeAt = loc[0] + 1
+			} else {
+				it.resumeAt = loc[0] - 1
+			}
+			return Match{Start: start, End: end}, true
+		}
+
+		it.row = advance
+	}
+
+	it.done = true
+	return Match{}, false
+}
+
+// findInLine returns the byte range of the match to report for text: the
+// first match starting at or after minStart when searching forward, the
+// last match starting at or before maxStart when searching backward.
+func (it *MatchIterator) findInLine(text string, minStart, maxStart int) []int {
+	all := it.re.FindAllStringIndex(text, -1)
+	if len(all) == 0 {
+		return nil
+	}
+	if it.dir == Forward {
+		for _, loc := range all {
+			if loc[0] >= minStart {
+				return loc
+			}
+		}
+		return nil
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i][0] <= maxStart {
+			return all[i]
+		}
+	}
+	return nil
+}
+
+// colByteOffset returns the byte offset, within the string formed by
+// joining texts, of column x on physical row y - or false if y isn't one
+// of rows.
+func colByteOffset(rows []int, texts []string, y, x int) (int, bool) {
+	acc := 0
+	for i, row := range rows {
+		if row != y {
+			acc += len(texts[i])
+			continue
+		}
+		runes := []rune(texts[i])
+		if x < 0 {
+			x = 0
+		}
+		if x > len(runes) {
+			x = len(runes)
+		}
+		return acc + len(string(runes[:x])), true
+	}
+	return 0, false
+}
+
+// logicalLine returns the rows making up the soft-wrapped logical line that
+// contains row, along with each row's trimmed text, capped at
+// maxWrappedLinesFollowed rows and always including row itself.
+func (v *Terminal) logicalLine(row int) (rows []int, texts []string) {
+	for back := 0; back < maxWrappedLinesFollowed-1; back++ {
+		prev, ok := v.line(row - 1)
+		if !ok || !prev.Wrapped {
+			break
+		}
+		row--
+	}
+
+	for len(rows) < maxWrappedLinesFollowed {
+		l, ok := v.line(row)
+		if !ok {
+			break
+		}
+		rows = append(rows, row)
+		texts = append(texts, strings.TrimRight(string(l.Content), " "))
+		if !l.Wrapped {
+			break
+		}
+		row++
+	}
+
+	return rows, texts
+}
+
+// pointsForMatch converts a [start, end) byte range within the string
+// formed by joining texts back into Points against rows.
+func pointsForMatch(rows []int, texts []string, startByte, endByte int) (Point, Point) {
+	find := func(byteOffset int) Point {
+		acc := 0
+		for i, t := range texts {
+			l := len(t)
+			if byteOffset < acc+l || i == len(texts)-1 {
+				return Point{Y: rows[i], X: utf8.RuneCountInString(t[:clamp(byteOffset-acc, 0, l)])}
+			}
+			acc += l
+		}
+		return Point{Y: rows[len(rows)-1], X: 0}
+	}
+	// endByte is exclusive; report the last matched rune, not one past it.
+	return find(startByte), find(prevRuneBoundary(texts, startByte, endByte))
+}
+
+func prevRuneBoundary(texts []string, startByte, endByte int) int {
+	if endByte <= startByte {
+		return startByte
+	}
+	joined := strings.Join(texts, "")
+	if endByte > len(joined) {
+		endByte = len(joined)
+	}
+	_, size := utf8.DecodeLastRuneInString(joined[:endByte])
+	if size == 0 {
+		return startByte
+	}
+	return endByte - size
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package midterm
+
+import "testing"
+
+func TestSearchForwardBoundedByStartX(t *testing.T) {
+	v := NewTerminal(3, 20)
+	v.Write([]byte("foo foo foo"))
+
+	it, err := v.Search("foo", Forward, Point{Y: 0, X: 4})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	m, ok := it.Next()
+	if !ok {
+		t.Fatalf("expected a match at or after column 4")
+	}
+	if m.Start.X < 4 {
+		t.Fatalf("match started at column %d, before start.X=4", m.Start.X)
+	}
+	if m.Start.X != 4 {
+		t.Fatalf("expected the match beginning at column 4 itself, got %d", m.Start.X)
+	}
+}
+
+func TestSearchBackwardBoundedByStartX(t *testing.T) {
+	v := NewTerminal(3, 20)
+	v.Write([]byte("foo foo foo"))
+
+	it, err := v.Search("foo", Backward, Point{Y: 0, X: 6})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	m, ok := it.Next()
+	if !ok {
+		t.Fatalf("expected a match at or before column 6")
+	}
+	if m.Start.X > 6 {
+		t.Fatalf("match started at column %d, after start.X=6", m.Start.X)
+	}
+	if m.Start.X != 4 {
+		t.Fatalf("expected the match beginning at column 4, got %d", m.Start.X)
+	}
+}
+
+func TestSearchFollowsSoftWrap(t *testing.T) {
+	v := NewTerminal(3, 5)
+	v.Write([]byte("helloworld"))
+
+	it, err := v.Search("oworl", Forward, Point{Y: 0, X: 0})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	m, ok := it.Next()
+	if !ok {
+		t.Fatalf("expected a match spanning the soft wrap")
+	}
+	if m.Start.Y != 0 || m.End.Y != 1 {
+		t.Fatalf("expected match to span rows 0-1, got %+v", m)
+	}
+}
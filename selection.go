@@ -0,0 +1,256 @@
+package midterm
+
+import "strings"
+
+// DefaultSemanticEscapeChars are the runes that bound a Semantic selection
+// by default, matching common double-click word selection behaviour.
+const DefaultSemanticEscapeChars = ",│`|:\"' ()[]{}<>\t"
+
+// SelectionType is the shape a selection expands with.
+type SelectionType int
+
+const (
+	// SelectionSimple selects exactly the cells between the anchor and the
+	// current point.
+	SelectionSimple SelectionType = iota
+
+	// SelectionSemantic expands the anchor and point outward to the
+	// nearest SemanticEscapeChars rune on each side, like a double-click.
+	SelectionSemantic
+
+	// SelectionLines selects whole rows, following soft-wrap continuations.
+	SelectionLines
+
+	// SelectionBlock selects a rectangular region between the anchor and
+	// current point, independent of row content.
+	SelectionBlock
+)
+
+// Side identifies which half of a cell a point falls in, so a click on the
+// right half of a cell can anchor or extend a selection starting at the
+// next cell.
+type Side int
+
+const (
+	SideLeft Side = iota
+	SideRight
+)
+
+// SelectionRange describes a resolved selection as a half-open-free,
+// inclusive range of Points.
+type SelectionRange struct {
+	Type       SelectionType
+	Start, End Point
+}
+
+// selection is the in-progress selection state; it is nil when there is no
+// active selection.
+type selection struct {
+	kind       SelectionType
+	anchor     Point
+	anchorSide Side
+	point      Point
+	side       Side
+}
+
+// StartSelection begins a new selection of the given kind, anchored at p.
+func (v *Terminal) StartSelection(kind SelectionType, p Point, side Side) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.selection = &selection{kind: kind, anchor: p, anchorSide: side, point: p, side: side}
+}
+
+// UpdateSelection moves the live end of the active selection to p. It is a
+// no-op if no selection is active.
+func (v *Terminal) UpdateSelection(p Point, side Side) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.selection == nil {
+		return
+	}
+	v.selection.point = p
+	v.selection.side = side
+}
+
+// translateSelection shifts the active selection's coordinates by deltaY
+// rows, so it keeps pointing at the same content after a full-screen scroll
+// (deltaY negative when scrolling up pushes rows into scrollback).
+func (v *Terminal) translateSelection(deltaY int) {
+	if v.selection == nil {
+		return
+	}
+	v.selection.anchor.Y += deltaY
+	v.selection.point.Y += deltaY
+}
+
+// ClearSelection removes the active selection, if any.
+func (v *Terminal) ClearSelection() {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.selection = nil
+}
+
+// SelectionRange resolves the active selection's anchor/point pair into a
+// concrete, ordered range, or nil if there is no active selection.
+func (v *Terminal) SelectionRange() *SelectionRange {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	return v.selectionRangeLocked()
+}
+
+func (v *Terminal) selectionRangeLocked() *SelectionRange {
+	sel := v.selection
+	if sel == nil {
+		return nil
+	}
+
+	startP, startSide, endP, endSide := sel.anchor, sel.anchorSide, sel.point, sel.side
+	if pointLess(endP, startP) {
+		startP, startSide, endP, endSide = endP, endSide, startP, startSide
+	}
+
+	switch sel.kind {
+	case SelectionSemantic:
+		startP = v.semanticExpand(startP, false)
+		endP = v.semanticExpand(endP, true)
+	case SelectionLines:
+		startP.X = 0
+		for {
+			prev, ok := v.line(startP.Y - 1)
+			if !ok || !prev.Wrapped {
+				break
+			}
+			startP.Y--
+		}
+		for {
+			cur, ok := v.line(endP.Y)
+			if !ok || !cur.Wrapped {
+				break
+			}
+			endP.Y++
+		}
+		if l, ok := v.line(endP.Y); ok {
+			endP.X = len(l.Content) - 1
+		}
+	case SelectionBlock:
+		// pointLess only ordered the pair by Y (X was just a tiebreaker),
+		// so a block dragged e.g. bottom-left to top-right can still have
+		// startP.X > endP.X here; normalize the columns independently of
+		// the rows.
+		if startP.X > endP.X {
+			startP.X, endP.X = endP.X, startP.X
+		}
+	default: // SelectionSimple
+		if startSide == SideRight {
+			startP.X++
+		}
+		if endSide == SideLeft {
+			endP.X--
+		}
+	}
+
+	return &SelectionRange{Type: sel.kind, Start: startP, End: endP}
+}
+
+func pointLess(a, b Point) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
+
+// semanticExpand walks from p outward (backward if !forward, forward if
+// forward) until it hits a rune in SemanticEscapeChars, and returns the
+// point just inside that boundary.
+func (v *Terminal) semanticExpand(p Point, forward bool) Point {
+	escape := v.SemanticEscapeChars
+	if escape == "" {
+		escape = DefaultSemanticEscapeChars
+	}
+
+	cur := p
+	for {
+		l, ok := v.line(cur.Y)
+		if !ok || cur.X < 0 || cur.X >= len(l.Content) {
+			break
+		}
+		if strings.ContainsRune(escape, l.Content[cur.X]) {
+			break
+		}
+
+		next := cur
+		if forward {
+			next.X++
+		} else {
+			next.X--
+		}
+
+		if forward && next.X >= len(l.Content) {
+			break
+		}
+		if !forward && next.X < 0 {
+			break
+		}
+		if strings.ContainsRune(escape, l.Content[next.X]) {
+			break
+		}
+		cur = next
+	}
+	return cur
+}
+
+// SelectionText extracts the text of the active selection, joining
+// soft-wrapped rows without a newline and inserting "\n" between hard line
+// breaks. Wide-char spacer cells are skipped.
+func (v *Terminal) SelectionText() string {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+
+	r := v.selectionRangeLocked()
+	if r == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for y := r.Start.Y; y <= r.End.Y; y++ {
+		l, ok := v.line(y)
+		if !ok {
+			continue
+		}
+
+		startX, endX := 0, len(l.Content)-1
+		switch r.Type {
+		case SelectionBlock:
+			startX, endX = r.Start.X, r.End.X
+		default:
+			if y == r.Start.Y {
+				startX = r.Start.X
+			}
+			if y == r.End.Y {
+				endX = r.End.X
+			}
+		}
+
+		for x := startX; x <= endX && x < len(l.Content); x++ {
+			if x < len(l.Wide) && l.Wide[x] == WideSpacer {
+				continue
+			}
+			b.WriteRune(l.Content[x])
+			if x < len(l.Combining) {
+				for _, c := range l.Combining[x] {
+					b.WriteRune(c)
+				}
+			}
+		}
+
+		if y == r.End.Y {
+			break
+		}
+		if r.Type != SelectionBlock && l.Wrapped {
+			continue // soft wrap: no newline
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package midterm
+
+import "testing"
+
+func TestSelectionRangeSimple(t *testing.T) {
+	v := NewTerminal(3, 10)
+	v.Write([]byte("hello world"))
+
+	v.StartSelection(SelectionSimple, Point{Y: 0, X: 0}, SideLeft)
+	v.UpdateSelection(Point{Y: 0, X: 4}, SideLeft)
+
+	r := v.SelectionRange()
+	if r == nil {
+		t.Fatalf("expected an active selection")
+	}
+	if r.Start != (Point{Y: 0, X: 0}) || r.End != (Point{Y: 0, X: 3}) {
+		t.Fatalf("unexpected simple range: %+v", r)
+	}
+}
+
+func TestSelectionRangeBlockNormalizesColumnsIndependently(t *testing.T) {
+	v := NewTerminal(3, 10)
+	v.Write([]byte("hello world"))
+
+	// Drag from bottom-left to top-right: Y is already ordered by pointLess,
+	// but X is backwards relative to a naive swap.
+	v.StartSelection(SelectionBlock, Point{Y: 1, X: 0}, SideLeft)
+	v.UpdateSelection(Point{Y: 0, X: 5}, SideLeft)
+
+	r := v.SelectionRange()
+	if r == nil {
+		t.Fatalf("expected an active selection")
+	}
+	if r.Start.X != 0 || r.End.X != 5 {
+		t.Fatalf("expected columns normalized to [0, 5], got start.X=%d end.X=%d", r.Start.X, r.End.X)
+	}
+}
+
+func TestSelectionTextSkipsNewlineOnSoftWrap(t *testing.T) {
+	v := NewTerminal(3, 5)
+	v.Write([]byte("helloworld"))
+
+	v.StartSelection(SelectionSimple, Point{Y: 0, X: 0}, SideLeft)
+	v.UpdateSelection(Point{Y: 1, X: 4}, SideRight)
+
+	got := v.SelectionText()
+	want := "helloworld"
+	if got != want {
+		t.Fatalf("SelectionText across a soft wrap = %q, want %q", got, want)
+	}
+}
+
+func TestSelectionLinesFollowsWrapBothWays(t *testing.T) {
+	v := NewTerminal(3, 5)
+	v.Write([]byte("helloworld"))
+
+	v.StartSelection(SelectionLines, Point{Y: 1, X: 2}, SideLeft)
+	v.UpdateSelection(Point{Y: 1, X: 2}, SideLeft)
+
+	r := v.SelectionRange()
+	if r == nil {
+		t.Fatalf("expected an active selection")
+	}
+	if r.Start.Y != 0 || r.End.Y != 1 {
+		t.Fatalf("expected the selection to expand to both wrapped rows, got %+v", r)
+	}
+}
@@ -17,6 +17,16 @@ type Terminal struct {
 	// The title of the terminal
 	Title string
 
+	// IconTitle is the icon title, set separately from Title by OSC 1
+	// (OSC 0 sets both).
+	IconTitle string
+
+	// titleStack holds titles saved by XTPUSH (CSI 22 t), most recent last.
+	titleStack []titleEntry
+
+	// onTitleChange is called whenever Title or IconTitle changes.
+	onTitleChange OnTitleChangeFunc
+
 	// Alt is either the alternate screen (if !IsAlt) or the main screen (if
 	// IsAlt).
 	Alt *Screen
@@ -51,6 +61,9 @@ type Terminal struct {
 	// to the next line if another character is printed.
 	wrap bool
 
+	// mode holds the DECSET/DECRST-controlled modes; see TermMode.
+	mode TermMode
+
 	*ansicode.Decoder
 
 	// onResize is a hook called every time the terminal resizes.
@@ -60,6 +73,29 @@ type Terminal struct {
 	// of the visible screen region.
 	onScrollback OnScrollbackFunc
 
+	// scrollback holds lines that have fallen off the top of the visible
+	// screen, oldest first, bounded to scrollbackLimit.
+	scrollback []Line
+
+	// scrollbackLimit is the maximum number of lines retained in
+	// scrollback. Zero means DefaultScrollbackLimit applies.
+	scrollbackLimit int
+
+	// SemanticEscapeChars are the runes a Semantic selection stops at.
+	// Empty means DefaultSemanticEscapeChars applies.
+	SemanticEscapeChars string
+
+	// selection is the active selection, or nil.
+	selection *selection
+
+	// viCursor is the read-only vi-mode navigation cursor, or nil when vi
+	// mode isn't active.
+	viCursor *ViCursor
+
+	// viSelecting indicates that Motion should also drive the live end of
+	// the active selection.
+	viSelecting bool
+
 	// for synchronizing e.g. writes and async resizing
 	mut sync.Mutex
 }
@@ -74,6 +110,10 @@ type Cursor struct {
 
 	// S is the cursor style.
 	S ansicode.CursorStyle
+
+	// Hyperlink is the OSC 8 hyperlink currently in effect, if any; every
+	// cell subsequently put is stamped with it.
+	Hyperlink HyperlinkID
 }
 
 // ScrollRegion represents a region of the terminal that is
@@ -102,6 +142,7 @@ func NewAutoResizingTerminal() *Terminal {
 func NewTerminal(rows, cols int) *Terminal {
 	v := &Terminal{
 		Screen: newScreen(rows, cols),
+		mode:   defaultTermMode,
 	}
 	v.Decoder = ansicode.NewDecoder(v)
 	v.reset()
@@ -120,6 +161,8 @@ func (v *Terminal) Reset() {
 	v.mut.Lock()
 	defer v.mut.Unlock()
 	v.reset()
+	v.selection = nil
+	v.viCursor = nil
 }
 
 func (v *Terminal) UsedHeight() int {
@@ -210,30 +253,16 @@ func (v *Terminal) resize(h, w int) {
 	if v.Alt != nil {
 		v.Alt.resize(h, w)
 	}
-}
-
-// put puts r onto the current cursor's position, then advances the cursor.
-func (v *Terminal) put(r rune) {
-	if v.wrap {
-		v.Cursor.X = 0
-		v.moveDown()
-		v.wrap = false
-	}
-	x, y, f := v.Cursor.X, v.Cursor.Y, v.Cursor.F
-	v.paint(y, x, f, r)
-	if y > v.MaxY {
-		v.MaxY = y
-	}
-	if x > v.MaxX {
-		v.MaxX = x
-	}
-	v.advance()
+	v.selection = nil
 }
 
 // advance advances the cursor, wrapping to the next line if need be.
 func (v *Terminal) advance() {
 	if !v.AutoResizeX && v.Cursor.X == v.Width-1 {
-		v.wrap = true
+		if v.mode&LineWrap != 0 {
+			v.wrap = true
+			v.Wrapped[v.Cursor.Y] = true
+		}
 	} else {
 		v.moveRel(0, 1)
 		v.changed(v.Cursor.Y, true)
@@ -247,9 +276,23 @@ func (v *Terminal) resizeY(h int) {
 	}
 }
 
+// swapAlt flips between the main and alternate screens. It is the single
+// place that keeps IsAlt, mode's AltScreen bit, and Screen/Alt in sync;
+// SetMode/UnsetMode call it whenever AltScreen changes rather than
+// touching IsAlt or the screens themselves.
 func (v *Terminal) swapAlt() {
+	if v.Alt != nil && v.Alt.linkTable == nil {
+		// Share one hyperlink table across both screens so interning
+		// survives an alt-screen swap instead of starting over.
+		v.Alt.linkTable = v.Screen.linkTable
+	}
 	v.IsAlt = !v.IsAlt
 	v.Screen, v.Alt = v.Alt, v.Screen
+	if v.IsAlt {
+		v.mode |= AltScreen
+	} else {
+		v.mode &^= AltScreen
+	}
 }
 
 func scrollUp[T any](arr [][]T, positions, start, end int, empty T) {
@@ -459,25 +502,51 @@ func insertEmpties[T any](arr [][]T, row, col, ps int, empty T) {
 }
 
 func (v *Terminal) insertCharacters(n int) {
-	insertEmpties(v.Content, v.Cursor.Y, v.Cursor.X, n, ' ')
-	v.Format.Insert(v.Cursor.Y, v.Cursor.X, v.Cursor.F, n)
-	v.changed(v.Cursor.Y, false)
+	y := v.Cursor.Y
+	// Characters pushed off the end of the row by the insertion are gone
+	// for good; unref whatever hyperlinks they carried.
+	v.unrefHyperlinkRange(y, len(v.Hyperlinks[y])-n, n)
+	insertEmpties(v.Content, y, v.Cursor.X, n, ' ')
+	insertEmpties(v.Wide, y, v.Cursor.X, n, WideNone)
+	insertEmpties(v.Combining, y, v.Cursor.X, n, []rune(nil))
+	insertEmpties(v.Hyperlinks, y, v.Cursor.X, n, HyperlinkID(0))
+	v.Format.Insert(y, v.Cursor.X, v.Cursor.F, n)
+	v.repairWidePairs(y)
+	v.changed(y, false)
 }
 
 func (v *Terminal) deleteCharacters(n int) {
 	v.wrap = false // delete characters resets the wrap state.
-	deleteCharacters(v.Content, v.Cursor.Y, v.Cursor.X, n, ' ')
-	v.Format.Delete(v.Cursor.Y, v.Cursor.X, n)
-	v.changed(v.Cursor.Y, false)
+	y, x := v.Cursor.Y, v.Cursor.X
+	if x >= 0 && x < len(v.Wide[y]) && v.Wide[y][x] == WideLead {
+		// Deleting a wide lead cell also takes its spacer, so the pair is
+		// never split.
+		n++
+	}
+	// The deleted columns are shifted out without being copied anywhere
+	// else; unref whatever hyperlinks they carried.
+	v.unrefHyperlinkRange(y, x, n)
+	deleteCharacters(v.Content, y, x, n, ' ')
+	deleteCharacters(v.Wide, y, x, n, WideNone)
+	deleteCharacters(v.Combining, y, x, n, []rune(nil))
+	deleteCharacters(v.Hyperlinks, y, x, n, HyperlinkID(0))
+	v.Format.Delete(y, x, n)
+	v.repairWidePairs(y)
+	v.changed(y, false)
 }
 
 func (v *Terminal) eraseCharacters(n int) {
 	v.wrap = false // erase characters resets the wrap state.
-	eraseCharacters(v.Content, v.Cursor.Y, v.Cursor.X, n, ' ')
+	y := v.Cursor.Y
+	eraseCharacters(v.Content, y, v.Cursor.X, n, ' ')
+	eraseCharacters(v.Wide, y, v.Cursor.X, n, WideNone)
+	eraseCharacters(v.Combining, y, v.Cursor.X, n, []rune(nil))
 	for i := 0; i < n; i++ {
-		v.Format.Paint(v.Cursor.Y, v.Cursor.X+i, v.Cursor.F)
+		v.Format.Paint(y, v.Cursor.X+i, v.Cursor.F)
+		v.stampHyperlink(y, v.Cursor.X+i, 0)
 	}
-	v.changed(v.Cursor.Y, false)
+	v.repairWidePairs(y)
+	v.changed(y, false)
 }
 
 func (v *Terminal) insertLines(n int) {
@@ -487,7 +556,13 @@ func (v *Terminal) insertLines(n int) {
 		return
 	}
 	v.wrap = false
+	// Rows pushed off the bottom of the region by the insertion are gone
+	// for good; unref whatever hyperlinks they carried.
+	v.unrefHyperlinkRows(end-n+1, end)
 	insertLines(v.Content, v.Cursor.Y, n, start, end, ' ')
+	insertLines(v.Wide, v.Cursor.Y, n, start, end, WideNone)
+	insertLines(v.Combining, v.Cursor.Y, n, start, end, []rune(nil))
+	insertLines(v.Hyperlinks, v.Cursor.Y, n, start, end, HyperlinkID(0))
 	insertLinesShallow(v.Format.Rows, v.Cursor.Y, n, start, end, func() *Region {
 		return &Region{Size: v.Width, F: v.Cursor.F}
 	})
@@ -502,7 +577,13 @@ func (v *Terminal) deleteLines(n int) {
 		return
 	}
 	v.wrap = false // delete lines resets the wrap state.
+	// The deleted rows are overwritten in place rather than copied
+	// anywhere else; unref whatever hyperlinks they carried.
+	v.unrefHyperlinkRows(v.Cursor.Y, v.Cursor.Y+n-1)
 	deleteLines(v.Content, v.Cursor.Y, n, start, end, ' ')
+	deleteLines(v.Wide, v.Cursor.Y, n, start, end, WideNone)
+	deleteLines(v.Combining, v.Cursor.Y, n, start, end, []rune(nil))
+	deleteLines(v.Hyperlinks, v.Cursor.Y, n, start, end, HyperlinkID(0))
 	deleteLinesShallow(v.Format.Rows, v.Cursor.Y, n, start, end, func() *Region {
 		return &Region{Size: v.Width, F: v.Cursor.F}
 	})
@@ -514,7 +595,16 @@ func (v *Terminal) deleteLines(n int) {
 func (v *Terminal) scrollDownN(n int) {
 	v.wrap = false // scroll down resets the wrap state.
 	start, end := v.scrollRegion()
+	if start == 0 {
+		v.translateSelection(n)
+	}
+	// Rows scrolled off the bottom of the region have no scrollback
+	// equivalent to move into; unref whatever hyperlinks they carried.
+	v.unrefHyperlinkRows(end-n+1, end)
 	scrollDown(v.Content, n, start, end, ' ')
+	scrollDown(v.Wide, n, start, end, WideNone)
+	scrollDown(v.Combining, n, start, end, []rune(nil))
+	scrollDown(v.Hyperlinks, n, start, end, HyperlinkID(0))
 	scrollDownShallow(v.Format.Rows, n, start, end, func() *Region {
 		return &Region{Size: v.Width, F: v.Cursor.F}
 	})
@@ -524,20 +614,43 @@ func (v *Terminal) scrollDownN(n int) {
 }
 
 func (v *Terminal) scrollUpN(n int) {
-	if v.onScrollback != nil {
-		for i := 0; i < n; i++ {
-			// v.onScrollback(Line{v.Content[i], v.Format[i]})
-		}
-	}
 	// v.wrap = false // scroll up does NOT reset the wrap state.
 	start, end := v.scrollRegion()
+
+	// Only a scroll region whose top is the screen's top row feeds
+	// scrollback; lines scrolled out of a DECSTBM sub-region starting lower
+	// down are just discarded, same as a real terminal.
+	if start == 0 {
+		// The rows scrolling off the top move into scrollback, hyperlink
+		// refs included, rather than being unreffed here.
+		for i := 0; i < n && i <= end && i < len(v.Content); i++ {
+			v.pushScrollback(Line{
+				Content:    append([]rune(nil), v.Content[i]...),
+				Format:     append([]Format(nil), v.Format[i]...),
+				Wrapped:    v.Wrapped[i],
+				Wide:       append([]WideKind(nil), v.Wide[i]...),
+				Combining:  append([][]rune(nil), v.Combining[i]...),
+				Hyperlinks: append([]HyperlinkID(nil), v.Hyperlinks[i]...),
+			})
+		}
+		v.translateSelection(-n)
+	} else {
+		v.unrefHyperlinkRows(start, start+n-1)
+	}
+
 	scrollUp(v.Content, n, start, end, ' ')
+	scrollUp(v.Wide, n, start, end, WideNone)
+	scrollUp(v.Combining, n, start, end, []rune(nil))
+	scrollUp(v.Hyperlinks, n, start, end, HyperlinkID(0))
 	scrollUpShallow(v.Format.Rows, n, start, end, func() *Region {
 		return &Region{Size: v.Width, F: v.Cursor.F}
 	})
 	scrollUpShallow(v.Changes, n, start, end, func() uint64 {
 		return 1
 	})
+	scrollUpShallow(v.Wrapped, n, start, end, func() bool {
+		return false
+	})
 }
 
 func (v *Terminal) scrollRegion() (int, int) {
@@ -0,0 +1,115 @@
+package midterm
+
+// TitleStackMax bounds the depth of the window/icon title stack, so a
+// hostile process that pushes without ever popping can't grow it forever.
+const TitleStackMax = 4096
+
+// titleEntry is a single saved (window title, icon title) pair.
+type titleEntry struct {
+	title string
+	icon  string
+}
+
+// OnTitleChangeFunc is called whenever the window or icon title changes.
+type OnTitleChangeFunc func(title, icon string)
+
+// OnTitleChange sets a hook that is called every time the window or icon
+// title changes, whether via OSC 0/1/2 or an XTPOP restoring a pushed one.
+func (v *Terminal) OnTitleChange(f OnTitleChangeFunc) {
+	v.mut.Lock()
+	v.onTitleChange = f
+	v.mut.Unlock()
+}
+
+// SetTitle sets both the window and icon titles (OSC 0).
+func (v *Terminal) SetTitle(title string) {
+	v.mut.Lock()
+	v.Title = title
+	v.IconTitle = title
+	f, t, i := v.onTitleChange, v.Title, v.IconTitle
+	v.mut.Unlock()
+	if f != nil {
+		f(t, i)
+	}
+}
+
+// SetIconTitle sets the icon title only (OSC 1).
+func (v *Terminal) SetIconTitle(title string) {
+	v.mut.Lock()
+	v.IconTitle = title
+	f, t, i := v.onTitleChange, v.Title, v.IconTitle
+	v.mut.Unlock()
+	if f != nil {
+		f(t, i)
+	}
+}
+
+// SetWindowTitle sets the window title only (OSC 2).
+func (v *Terminal) SetWindowTitle(title string) {
+	v.mut.Lock()
+	v.Title = title
+	f, t, i := v.onTitleChange, v.Title, v.IconTitle
+	v.mut.Unlock()
+	if f != nil {
+		f(t, i)
+	}
+}
+
+// PushTitle saves the current window and icon titles on the title stack
+// (CSI 22 ; 0 t).
+func (v *Terminal) PushTitle() {
+	v.pushTitleTarget(0)
+}
+
+// PopTitle restores the most recently pushed window and icon titles (CSI
+// 23 ; 0 t). It is a no-op if the stack is empty.
+func (v *Terminal) PopTitle() {
+	v.popTitleTarget(0)
+}
+
+// XTPushTitle handles CSI Ps ; Pt t's push form (Ps == 22): target mirrors
+// Pt. Both the window and icon titles are saved whatever target is; only
+// XTPopTitle's target decides which of them are restored.
+func (v *Terminal) XTPushTitle(target int) {
+	v.pushTitleTarget(target)
+}
+
+// XTPopTitle handles CSI Ps ; Pt t's pop form (Ps == 23), restoring the
+// fields selected by target the same way XTPushTitle saved them.
+func (v *Terminal) XTPopTitle(target int) {
+	v.popTitleTarget(target)
+}
+
+func (v *Terminal) pushTitleTarget(target int) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+
+	if len(v.titleStack) >= TitleStackMax {
+		v.titleStack = v.titleStack[1:]
+	}
+	_ = target // xterm stacks titles in pairs regardless of Pt; kept for symmetry with XTPopTitle.
+	v.titleStack = append(v.titleStack, titleEntry{title: v.Title, icon: v.IconTitle})
+}
+
+func (v *Terminal) popTitleTarget(target int) {
+	v.mut.Lock()
+	if len(v.titleStack) == 0 {
+		v.mut.Unlock()
+		return
+	}
+
+	e := v.titleStack[len(v.titleStack)-1]
+	v.titleStack = v.titleStack[:len(v.titleStack)-1]
+	if target == 0 || target == 2 {
+		v.Title = e.title
+	}
+	if target == 0 || target == 1 {
+		v.IconTitle = e.icon
+	}
+
+	f, t, i := v.onTitleChange, v.Title, v.IconTitle
+	v.mut.Unlock()
+	if f != nil {
+		f(t, i)
+	}
+}
@@ -0,0 +1,382 @@
+package midterm
+
+import "strings"
+
+// ViMotion is a single vi-style navigation step for the read-only vi-mode
+// cursor.
+type ViMotion int
+
+const (
+	ViUp ViMotion = iota
+	ViDown
+	ViLeft
+	ViRight
+	ViWord
+	ViWordEnd
+	ViWordBack
+	ViBracket
+	ViFirst
+	ViLast
+	ViFirstOccupied
+	ViHigh
+	ViMiddle
+	ViLow
+	ViSemanticLeft
+	ViSemanticRight
+	ViLineStart
+	ViLineEnd
+)
+
+// ViCursor is a read-only navigation cursor layered on top of the terminal,
+// so a TUI wrapper can implement copy-mode without disturbing the real
+// Cursor. Its Point can reach above row 0 into scrollback.
+type ViCursor struct {
+	Point
+}
+
+// EnterViMode starts vi navigation at the real cursor's current position.
+// It is a no-op if vi mode is already active.
+func (v *Terminal) EnterViMode() {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.viCursor != nil {
+		return
+	}
+	v.viCursor = &ViCursor{Point: Point{Y: v.Cursor.Y, X: v.Cursor.X}}
+}
+
+// ExitViMode leaves vi navigation and drops any vi-driven selection anchor.
+func (v *Terminal) ExitViMode() {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.viCursor = nil
+	v.viSelecting = false
+}
+
+// ViCursorPosition returns the vi cursor's current position, and false if
+// vi mode isn't active.
+func (v *Terminal) ViCursorPosition() (Point, bool) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.viCursor == nil {
+		return Point{}, false
+	}
+	return v.viCursor.Point, true
+}
+
+// ViSelection starts (or restarts) a selection anchored at the vi cursor,
+// so subsequent Motion calls extend it as the cursor moves. It is a no-op
+// if vi mode isn't active.
+func (v *Terminal) ViSelection(kind SelectionType) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.viCursor == nil {
+		return
+	}
+	v.selection = &selection{kind: kind, anchor: v.viCursor.Point, point: v.viCursor.Point}
+	v.viSelecting = true
+}
+
+// Motion moves the vi cursor by m, clamped to the content that actually
+// exists (the visible screen plus scrollback) and never landing on a
+// wide-char spacer cell. If a vi selection is active, its live end follows
+// the cursor.
+func (v *Terminal) Motion(m ViMotion) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.viCursor == nil {
+		return
+	}
+
+	p := v.viCursor.Point
+	switch m {
+	case ViUp:
+		p.Y--
+	case ViDown:
+		p.Y++
+	case ViLeft:
+		p.X--
+	case ViRight:
+		p.X++
+	case ViLineStart:
+		p.X = 0
+	case ViLineEnd:
+		if l, ok := v.line(p.Y); ok {
+			p.X = lastCol(l)
+		}
+	case ViFirstOccupied:
+		if l, ok := v.line(p.Y); ok {
+			p.X = firstOccupied(l)
+		}
+	case ViFirst:
+		p = Point{Y: -len(v.scrollback), X: 0}
+	case ViLast:
+		p = Point{Y: len(v.Content) - 1}
+		if l, ok := v.line(p.Y); ok {
+			p.X = lastCol(l)
+		}
+	case ViHigh:
+		p.Y = 0
+	case ViMiddle:
+		p.Y = v.Height / 2
+	case ViLow:
+		p.Y = v.Height - 1
+	case ViWord:
+		p = v.wordMotion(p, true)
+	case ViWordBack:
+		p = v.wordEndMotion(p, false)
+	case ViWordEnd:
+		p = v.wordEndMotion(p, true)
+	case ViSemanticLeft:
+		p = v.semanticExpand(p, false)
+	case ViSemanticRight:
+		p = v.semanticExpand(p, true)
+	case ViBracket:
+		p = v.bracketMotion(p)
+	}
+
+	p = v.clampPoint(p)
+	if v.wideAt(p.Y, p.X) == WideSpacer {
+		if l, ok := v.line(p.Y); ok && m == ViRight && p.X+1 < len(l.Content) {
+			p.X++
+		} else {
+			p.X--
+		}
+	}
+
+	v.viCursor.Point = p
+	if v.viSelecting && v.selection != nil {
+		v.selection.point = p
+	}
+}
+
+// clampPoint clamps p to the range of content that actually exists: Y
+// within [-len(scrollback), len(Content)-1], X within the row's bounds.
+func (v *Terminal) clampPoint(p Point) Point {
+	minY, maxY := -len(v.scrollback), len(v.Content)-1
+	if p.Y < minY {
+		p.Y = minY
+	}
+	if p.Y > maxY {
+		p.Y = maxY
+	}
+	if l, ok := v.line(p.Y); ok {
+		if p.X < 0 {
+			p.X = 0
+		}
+		if max := lastCol(l); p.X > max {
+			p.X = max
+		}
+	}
+	return p
+}
+
+func lastCol(l Line) int {
+	if len(l.Content) == 0 {
+		return 0
+	}
+	return len(l.Content) - 1
+}
+
+func firstOccupied(l Line) int {
+	for i, r := range l.Content {
+		if r != ' ' {
+			return i
+		}
+	}
+	return 0
+}
+
+// wideAt reports the WideKind of the cell at (y, x), across both the
+// visible screen and scrollback.
+func (v *Terminal) wideAt(y, x int) WideKind {
+	l, ok := v.line(y)
+	if !ok || x < 0 || x >= len(l.Wide) {
+		return WideNone
+	}
+	return l.Wide[x]
+}
+
+// stepPoint moves one cell forward (or backward), wrapping across row
+// boundaries, and reports the rune now under the cursor.
+func (v *Terminal) stepPoint(p Point, forward bool) (Point, rune, bool) {
+	l, ok := v.line(p.Y)
+	if !ok {
+		return p, 0, false
+	}
+
+	if forward {
+		p.X++
+		for p.X >= len(l.Content) {
+			p.Y++
+			l, ok = v.line(p.Y)
+			if !ok {
+				return p, 0, false
+			}
+			p.X = 0
+			if len(l.Content) == 0 {
+				continue
+			}
+			break
+		}
+	} else {
+		p.X--
+		for p.X < 0 {
+			p.Y--
+			l, ok = v.line(p.Y)
+			if !ok {
+				return p, 0, false
+			}
+			p.X = lastCol(l)
+		}
+	}
+
+	return p, l.Content[p.X], true
+}
+
+// runClass buckets a rune into whitespace (0), semantic-escape punctuation
+// (1), or word (2), the same three-way split vi's word motions use.
+func runClass(r rune, escape string) int {
+	switch {
+	case r == ' ':
+		return 0
+	case strings.ContainsRune(escape, r):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (v *Terminal) semanticEscapeChars() string {
+	if v.SemanticEscapeChars == "" {
+		return DefaultSemanticEscapeChars
+	}
+	return v.SemanticEscapeChars
+}
+
+// wordMotion implements vi's `w`: skip the rest of the current run, then
+// skip any whitespace run, landing on the start of the next word. (`b` is
+// wordEndMotion walking backward.)
+func (v *Terminal) wordMotion(p Point, forward bool) Point {
+	escape := v.semanticEscapeChars()
+
+	l, ok := v.line(p.Y)
+	if !ok {
+		return p
+	}
+	r := rune(' ')
+	if p.X >= 0 && p.X < len(l.Content) {
+		r = l.Content[p.X]
+	}
+	startClass := runClass(r, escape)
+
+	cur := p
+	for {
+		next, nr, ok := v.stepPoint(cur, forward)
+		if !ok {
+			return cur
+		}
+		cur, r = next, nr
+		if runClass(r, escape) != startClass {
+			break
+		}
+	}
+	for runClass(r, escape) == 0 {
+		next, nr, ok := v.stepPoint(cur, forward)
+		if !ok {
+			return cur
+		}
+		cur, r = next, nr
+	}
+	return cur
+}
+
+// wordEndMotion implements vi's `e` (forward): advance to the end of the
+// current or next word; and vi's `b` (backward): go back to the start of
+// the current or previous word.
+func (v *Terminal) wordEndMotion(p Point, forward bool) Point {
+	escape := v.semanticEscapeChars()
+
+	cur := p
+	next, r, ok := v.stepPoint(cur, forward)
+	if !ok {
+		return cur
+	}
+	cur = next
+	for runClass(r, escape) == 0 {
+		next, r, ok = v.stepPoint(cur, forward)
+		if !ok {
+			return cur
+		}
+		cur = next
+	}
+	curClass := runClass(r, escape)
+	for {
+		next, nr, ok := v.stepPoint(cur, forward)
+		if !ok || runClass(nr, escape) != curClass {
+			return cur
+		}
+		cur = next
+	}
+}
+
+var bracketPairs = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// bracketMotion implements vi's `%`: jump to the matching bracket,
+// accounting for nesting.
+func (v *Terminal) bracketMotion(p Point) Point {
+	l, ok := v.line(p.Y)
+	if !ok || p.X < 0 || p.X >= len(l.Content) {
+		return p
+	}
+	open := l.Content[p.X]
+
+	if close, isOpen := bracketPairs[open]; isOpen {
+		depth := 0
+		cur := p
+		for {
+			cl, _ := v.line(cur.Y)
+			switch cl.Content[cur.X] {
+			case open:
+				depth++
+			case close:
+				depth--
+				if depth == 0 {
+					return cur
+				}
+			}
+			next, _, ok := v.stepPoint(cur, true)
+			if !ok {
+				return p
+			}
+			cur = next
+		}
+	}
+
+	for o, c := range bracketPairs {
+		if c != open {
+			continue
+		}
+		depth := 0
+		cur := p
+		for {
+			cl, _ := v.line(cur.Y)
+			switch cl.Content[cur.X] {
+			case c:
+				depth++
+			case o:
+				depth--
+				if depth == 0 {
+					return cur
+				}
+			}
+			next, _, ok := v.stepPoint(cur, false)
+			if !ok {
+				return p
+			}
+			cur = next
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,128 @@
+package midterm
+
+import "github.com/mattn/go-runewidth"
+
+// WideKind classifies how a cell participates in a fullwidth rune, mirroring
+// Alacritty's Flags::WIDE_CHAR / WIDE_CHAR_SPACER.
+type WideKind uint8
+
+const (
+	// WideNone is an ordinary, single-width cell.
+	WideNone WideKind = iota
+
+	// WideLead is the cell holding the glyph of a width-2 rune.
+	WideLead
+
+	// WideSpacer is the cell immediately after a WideLead, reserved so the
+	// wide glyph has somewhere to render without overlapping the next
+	// character.
+	WideSpacer
+)
+
+// Cell is a self-contained view of a single screen cell: its base rune,
+// any zero-width combining marks, its display width, and its format.
+type Cell struct {
+	Rune      rune
+	Combining []rune
+	Width     int
+	Format    Format
+}
+
+// put puts r onto the current cursor's position, then advances the cursor.
+// Zero-width runes (combining marks) attach to the preceding cell instead of
+// occupying one of their own; fullwidth runes occupy the current cell plus a
+// WideSpacer cell after it, and never split across a line wrap.
+func (v *Terminal) put(r rune) {
+	width := runewidth.RuneWidth(r)
+	if width == 0 {
+		v.putCombining(r)
+		return
+	}
+
+	if v.wrap {
+		v.Cursor.X = 0
+		v.moveDown()
+		v.wrap = false
+	}
+
+	if width == 2 && v.Cursor.X == v.Width-1 && !v.AutoResizeX && v.mode&LineWrap != 0 {
+		// A wide rune never splits across a wrap: pad the trailing column
+		// with a blank filler and wrap early, same as the ordinary pending
+		// wrap above, including marking the row as soft-wrapped.
+		v.paint(v.Cursor.Y, v.Cursor.X, v.Cursor.F, ' ')
+		v.Wrapped[v.Cursor.Y] = true
+		v.Cursor.X = 0
+		v.moveDown()
+	}
+
+	x, y, f := v.Cursor.X, v.Cursor.Y, v.Cursor.F
+	v.paint(y, x, f, r)
+	v.Wide[y][x] = WideNone
+	v.Combining[y][x] = nil
+	v.stampHyperlink(y, x, v.Cursor.Hyperlink)
+
+	if width == 2 && x+1 < len(v.Wide[y]) {
+		v.paint(y, x+1, f, ' ')
+		v.Wide[y][x] = WideLead
+		v.Wide[y][x+1] = WideSpacer
+		v.Combining[y][x+1] = nil
+		v.stampHyperlink(y, x+1, v.Cursor.Hyperlink)
+	}
+
+	if y > v.MaxY {
+		v.MaxY = y
+	}
+	if x > v.MaxX {
+		v.MaxX = x
+	}
+
+	v.advance()
+	if width == 2 {
+		v.advance()
+	}
+}
+
+// repairWidePairs clears any WideLead or WideSpacer cell on row y left
+// without its partner, e.g. after a delete/insert/erase split a pair down
+// the middle.
+func (v *Screen) repairWidePairs(y int) {
+	if y < 0 || y >= len(v.Wide) {
+		return
+	}
+	row := v.Wide[y]
+	for x := range row {
+		switch row[x] {
+		case WideLead:
+			if x+1 >= len(row) || row[x+1] != WideSpacer {
+				v.clear(y, x, v.Format[y][x])
+			}
+		case WideSpacer:
+			if x == 0 || row[x-1] != WideLead {
+				v.clear(y, x, v.Format[y][x])
+			}
+		}
+	}
+}
+
+// putCombining attaches a zero-width rune to the previous non-spacer cell,
+// rather than advancing the cursor.
+func (v *Terminal) putCombining(r rune) {
+	y, x := v.Cursor.Y, v.Cursor.X
+	if !v.wrap {
+		// Ordinarily advance() has already moved the cursor past the
+		// glyph it attaches to. But when that glyph filled the last
+		// column, advance() sets wrap instead of moving the cursor, so
+		// Cursor.X is already pointing at the glyph itself.
+		x--
+	}
+	if y < 0 || y >= len(v.Content) || x < 0 {
+		return
+	}
+	if v.Wide[y][x] == WideSpacer {
+		x--
+	}
+	if x < 0 {
+		return
+	}
+	v.Combining[y][x] = append(v.Combining[y][x], r)
+}
@@ -0,0 +1,52 @@
+package midterm
+
+import "testing"
+
+func TestPutWideRuneForcedWrapMarksSoftWrap(t *testing.T) {
+	v := NewTerminal(3, 5)
+	v.Write([]byte("abcd"))
+	v.Write([]byte("中")) // width-2 rune, doesn't fit in the last column
+
+	if !v.Wrapped[0] {
+		t.Fatalf("expected row 0 to be marked as soft-wrapped")
+	}
+	if v.Content[1][0] != '中' {
+		t.Fatalf("expected the wide rune to land at the start of row 1, got %q", v.Content[1][0])
+	}
+}
+
+func TestPutWideRuneNoAutowrapDoesNotForceWrap(t *testing.T) {
+	v := NewTerminal(3, 5)
+	v.UnsetMode(LineWrap)
+	v.Write([]byte("abcd"))
+	v.Write([]byte("中"))
+
+	if v.Wrapped[0] {
+		t.Fatalf("row 0 should not be marked as soft-wrapped with LineWrap disabled")
+	}
+	if v.Content[0][4] != '中' {
+		t.Fatalf("expected the wide rune to have been placed in the last column, got %q", v.Content[0][4])
+	}
+}
+
+func TestRepairWidePairsClearsOrphanedHalf(t *testing.T) {
+	v := NewTerminal(3, 5)
+	v.Write([]byte("中")) // occupies columns 0 (lead) and 1 (spacer)
+
+	if v.Wide[0][0] != WideLead || v.Wide[0][1] != WideSpacer {
+		t.Fatalf("expected a wide lead/spacer pair at columns 0-1")
+	}
+
+	v.repairWidePairs(0)
+	if v.Wide[0][0] != WideLead || v.Wide[0][1] != WideSpacer {
+		t.Fatalf("an intact pair should survive repairWidePairs")
+	}
+
+	// Simulate a delete/insert splitting the pair down the middle.
+	v.Wide[0][1] = WideNone
+
+	v.repairWidePairs(0)
+	if v.Wide[0][0] != WideNone {
+		t.Fatalf("expected the orphaned lead half to be cleared, got %v", v.Wide[0][0])
+	}
+}